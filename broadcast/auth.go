@@ -0,0 +1,32 @@
+package broadcast
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrUnauthenticated is returned by an Authenticator when a request does
+// not carry valid credentials.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator authenticates incoming upgrade requests. A returned
+// identity is stored on the connection registry, so it is logged and can
+// later be used to disconnect a specific user via Server.Kick.
+type Authenticator interface {
+	Authenticate(r *http.Request) (identity string, err error)
+}
+
+// WithAuth sets the Authenticator used to gate incoming WS/WSS
+// connections. Requests that fail authentication are rejected with
+// http.Error before the Websocket upgrade happens.
+func WithAuth(a Authenticator) Option {
+	return func(s *Server) {
+		s.authenticator = a
+	}
+}
+
+// Kick disconnects every connection currently authenticated as identity,
+// returning how many were disconnected.
+func (s *Server) Kick(identity string) int {
+	return s.connections.Kick(identity)
+}
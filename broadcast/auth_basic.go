@@ -0,0 +1,33 @@
+package broadcast
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// NewBasicAuthenticator creates an Authenticator that checks incoming
+// requests against HTTP Basic credentials, using the username as the
+// identity.
+func NewBasicAuthenticator(credentials map[string]string) *BasicAuthenticator {
+	return &BasicAuthenticator{credentials: credentials}
+}
+
+// BasicAuthenticator authenticates requests using HTTP Basic auth.
+type BasicAuthenticator struct {
+	credentials map[string]string
+}
+
+// Authenticate implements Authenticator.
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (string, error) {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	want, ok := a.credentials[user]
+	if !ok || subtle.ConstantTimeCompare([]byte(pass), []byte(want)) != 1 {
+		return "", ErrUnauthenticated
+	}
+
+	return user, nil
+}
@@ -0,0 +1,72 @@
+package broadcast
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// NewBearerAuthenticator creates an Authenticator that checks incoming
+// requests against a static table of bearer tokens, each mapped to the
+// identity it authenticates as.
+func NewBearerAuthenticator(tokens map[string]string) *BearerAuthenticator {
+	return &BearerAuthenticator{tokens: tokens}
+}
+
+// NewBearerAuthenticatorFromFile creates a BearerAuthenticator from a file
+// with one "token identity" pair per line. Blank lines and lines starting
+// with '#' are ignored.
+func NewBearerAuthenticatorFromFile(name string) (*BearerAuthenticator, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := make(map[string]string)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid token line %q", line)
+		}
+
+		tokens[fields[0]] = fields[1]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewBearerAuthenticator(tokens), nil
+}
+
+// BearerAuthenticator authenticates requests using a static bearer token.
+type BearerAuthenticator struct {
+	tokens map[string]string
+}
+
+// Authenticate implements Authenticator.
+func (a *BearerAuthenticator) Authenticate(r *http.Request) (string, error) {
+	const prefix = "Bearer "
+
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return "", ErrUnauthenticated
+	}
+
+	identity, ok := a.tokens[strings.TrimPrefix(h, prefix)]
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,129 @@
+package broadcast
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// sendBufferSize is the number of pending outbound messages a client can
+// buffer before it is considered slow and disconnected.
+const sendBufferSize = 256
+
+// newClient creates a new client wrapping a Websocket connection.
+func newClient(ws *websocket.Conn, connections *Connections, messageType int, identity string) *client {
+	return &client{
+		ws:          ws,
+		send:        make(chan []byte, sendBufferSize),
+		connections: connections,
+		messageType: messageType,
+		identity:    identity,
+	}
+}
+
+// client wraps a Websocket connection with a bounded outbound queue.
+// All writes to the underlying connection happen in writePump, so it is
+// the only goroutine that owns the connection for writing purposes.
+//
+// mu guards closed and send so that Send and close never race: a send on
+// or a close of an already-closed channel would panic, so both operations
+// take mu and check closed before touching the channel.
+type client struct {
+	ws          *websocket.Conn
+	connections *Connections
+	messageType int
+	identity    string
+
+	mu     sync.Mutex
+	closed bool
+	send   chan []byte
+}
+
+// Send enqueues tx for delivery to the client, returning false if the
+// client is already closed or its outbound queue is full. Either way the
+// caller should treat the client as gone rather than retrying.
+func (c *client) Send(tx []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	select {
+	case c.send <- tx:
+		return true
+	default:
+		return false
+	}
+}
+
+// close marks the client closed and closes its outbound queue. It is
+// idempotent and safe to call from any goroutine.
+func (c *client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	c.closed = true
+	close(c.send)
+}
+
+// readPump reads and discards incoming messages, keeping the Websocket
+// read side drained so pongs and close frames are handled. It returns
+// when the connection is closed, at which point the client is removed
+// from the registry.
+func (c *client) readPump() {
+	defer c.connections.Delete(c)
+
+	c.ws.SetReadLimit(maxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		c.ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := c.ws.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump writes queued messages and periodic pings to the Websocket
+// connection. It is the single writer goroutine for the connection, so
+// broadcast writes and keepalive pings never race.
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.connections.Delete(c)
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// The registry closed the channel, so close the connection.
+				c.ws.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+			if err := c.ws.WriteMessage(c.messageType, msg); err != nil {
+				log.Printf("tx broadcast failed: %v", err)
+				return
+			}
+		case <-ticker.C:
+			c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.ws.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+				log.Printf("error sending ping: %v", err)
+				return
+			}
+		}
+	}
+}
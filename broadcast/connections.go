@@ -2,21 +2,19 @@ package broadcast
 
 import (
 	"sync"
-
-	"github.com/gorilla/websocket"
 )
 
 // NewConnections create a new Websocket connections registry.
 func NewConnections() *Connections {
 	return &Connections{
-		registry: make(map[*websocket.Conn]struct{}),
+		registry: make(map[*client]struct{}),
 	}
 }
 
 // Connections keeps track of active Websocket connections.
 type Connections struct {
 	mu       sync.RWMutex
-	registry map[*websocket.Conn]struct{}
+	registry map[*client]struct{}
 }
 
 // IsEmpty checks if there are registered connections.
@@ -27,39 +25,66 @@ func (c *Connections) IsEmpty() bool {
 	return len(c.registry) == 0
 }
 
-// Add adds a new Websocket connection to the registry.
-func (c *Connections) Add(ws *websocket.Conn) {
+// Add adds a new client to the registry.
+func (c *Connections) Add(cl *client) {
 	c.mu.Lock()
-	c.registry[ws] = struct{}{}
+	c.registry[cl] = struct{}{}
 	c.mu.Unlock()
 }
 
-// Delete removes a Websocket connection from the registry.
-// Connections are closed after being removed.
-func (c *Connections) Delete(ws *websocket.Conn) error {
+// Delete removes a client from the registry.
+// Its connection and send channel are closed after being removed.
+func (c *Connections) Delete(cl *client) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, ok := c.registry[cl]
+	delete(c.registry, cl)
+	c.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
 
-	delete(c.registry, ws)
-	return ws.Close()
+	cl.close()
+	return cl.ws.Close()
 }
 
 // Close closes all connections.
 func (c *Connections) Close() {
-	c.Iter(func(ws *websocket.Conn) bool {
-		ws.Close()
+	c.Iter(func(cl *client) bool {
+		c.Delete(cl)
 		return true
 	})
 }
 
-// Iter allows iterating the current connections.
-// Iteration stops when when false is returned.
-func (c *Connections) Iter(fn func(*websocket.Conn) bool) {
+// Iter allows iterating the current clients.
+// Iteration stops when false is returned.
+func (c *Connections) Iter(fn func(*client) bool) {
 	c.mu.RLock()
-	for ws := range c.registry {
-		if !fn(ws) {
+	clients := make([]*client, 0, len(c.registry))
+	for cl := range c.registry {
+		clients = append(clients, cl)
+	}
+	c.mu.RUnlock()
+
+	for _, cl := range clients {
+		if !fn(cl) {
 			return
 		}
 	}
-	c.mu.RUnlock()
+}
+
+// Kick disconnects every client authenticated as identity, returning how
+// many were disconnected.
+func (c *Connections) Kick(identity string) int {
+	var n int
+
+	c.Iter(func(cl *client) bool {
+		if cl.identity == identity {
+			c.Delete(cl)
+			n++
+		}
+		return true
+	})
+
+	return n
 }
@@ -0,0 +1,71 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// OutputFormat controls how broadcast lines are framed on the wire.
+type OutputFormat int
+
+const (
+	// FormatBinary sends each line as a Websocket binary message. This is
+	// the default, preserving prior behavior.
+	FormatBinary OutputFormat = iota
+	// FormatText sends each line as a Websocket text message, unchanged.
+	FormatText
+	// FormatJSON wraps each line in a JSON envelope carrying its offset
+	// and timestamp, sent as a Websocket text message.
+	FormatJSON
+)
+
+// Format sets the output format used when broadcasting lines to clients.
+func Format(f OutputFormat) Option {
+	return func(s *Server) {
+		s.format = f
+	}
+}
+
+// messageType returns the Websocket message type used to send f.
+func (f OutputFormat) messageType() int {
+	if f == FormatBinary {
+		return websocket.BinaryMessage
+	}
+	return websocket.TextMessage
+}
+
+// envelope is the JSON wire format used by FormatJSON. The offset lets
+// consumers detect gaps and resume from where they left off, and source
+// identifies which tailed file the line came from.
+type envelope struct {
+	Offset int64     `json:"offset"`
+	Time   time.Time `json:"ts"`
+	Line   string    `json:"line"`
+	Source string    `json:"source,omitempty"`
+}
+
+// encode renders line, read at the given offset from source, according
+// to s.format.
+func (s *Server) encode(line []byte, offset int64, source string) []byte {
+	if s.format != FormatJSON {
+		return line
+	}
+
+	env := envelope{
+		Offset: offset,
+		Time:   time.Now().UTC(),
+		Line:   string(line),
+		Source: source,
+	}
+
+	b, err := json.Marshal(env)
+	if err != nil {
+		log.Printf("error encoding JSON envelope: %v", err)
+		return line
+	}
+
+	return b
+}
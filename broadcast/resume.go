@@ -0,0 +1,129 @@
+package broadcast
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// controlReadWait bounds how long HandleWS waits for a resume control
+// message on connections that asked for one with "?resume=control" and
+// did not supply an offset on the URL.
+const controlReadWait = 200 * time.Millisecond
+
+// resumeControl is the control message clients can send right after the
+// upgrade to request a replay from a given byte offset. Source is only
+// required when more than one file is being tailed.
+type resumeControl struct {
+	ResumeFrom *int64 `json:"resume_from"`
+	Source     string `json:"source"`
+}
+
+// resumeOffset returns the source and byte offset a client asked to
+// resume from, either via the "source"/"offset"/"since_line" query
+// parameters on the upgrade URL, or, when the client flags its intent to
+// do so with "?resume=control", via a resumeControl message sent right
+// after the upgrade. When source is omitted and exactly one file is
+// being tailed, that file is assumed.
+//
+// The control-message probe is opt-in only: gorilla's Conn treats a read
+// error (including a deadline timeout) as permanent for the lifetime of
+// the connection, so blindly attempting it on every upgrade would poison
+// plain, non-resuming clients that never send anything.
+func (s *Server) resumeOffset(r *http.Request, ws *websocket.Conn) (source string, offset int64, ok bool) {
+	q := r.URL.Query()
+	source = q.Get("source")
+
+	for _, name := range []string{"offset", "since_line"} {
+		v := q.Get(name)
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err == nil {
+			offset, ok = n, true
+			break
+		}
+	}
+
+	if !ok && q.Get("resume") == "control" {
+		ws.SetReadDeadline(time.Now().Add(controlReadWait))
+		defer ws.SetReadDeadline(time.Time{})
+
+		if _, msg, err := ws.ReadMessage(); err == nil {
+			var ctrl resumeControl
+			if err := json.Unmarshal(msg, &ctrl); err == nil && ctrl.ResumeFrom != nil {
+				source, offset, ok = ctrl.Source, *ctrl.ResumeFrom, true
+			}
+		}
+	}
+
+	if !ok {
+		return "", 0, false
+	}
+
+	if source == "" {
+		source, ok = s.soleSource()
+		if !ok {
+			return "", 0, false
+		}
+	}
+
+	return source, offset, true
+}
+
+// catchUp streams the portion of source between offset and its live
+// reader's current offset directly to cl, before it is registered with
+// the connection registry. Lines written between the snapshot taken here
+// and cl being added to the registry are not replayed; a client that
+// needs a stronger guarantee should reconnect and resume again from its
+// last known offset.
+func (s *Server) catchUp(cl *client, source string, offset int64) error {
+	r, ok := s.getReader(source)
+	if !ok {
+		return errors.New("no live stream to catch up from")
+	}
+
+	upTo := r.GetOffset()
+	if offset >= upTo {
+		return nil
+	}
+
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	pos := offset
+	scanner := bufio.NewScanner(io.LimitReader(f, upTo-offset))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		pos += int64(len(line)) + 1 // +1 for the stripped newline
+
+		tx := s.encode(line, pos, source)
+		if s.format != FormatJSON {
+			tx = append([]byte(nil), tx...)
+		}
+
+		select {
+		case cl.send <- tx:
+		default:
+			return errors.New("client too slow to catch up")
+		}
+	}
+
+	return scanner.Err()
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -50,6 +51,18 @@ func Secure(certFile, keyFile string) Option {
 	}
 }
 
+// EnableCompression enables permessage-deflate compression (RFC 7692) on
+// accepted connections, using the given flate compression level. Log-tail
+// output is highly compressible line-oriented text, so this typically
+// reduces bandwidth for clients connecting over WAN links. Disabled by
+// default to preserve prior behavior.
+func EnableCompression(level int) Option {
+	return func(s *Server) {
+		s.compression = true
+		s.compressionLevel = level
+	}
+}
+
 // NewServer creates a new transactions broadcast server.
 func NewServer(options ...Option) *Server {
 	s := Server{
@@ -67,6 +80,7 @@ func NewServer(options ...Option) *Server {
 		}
 		return true
 	}
+	s.upgrader.EnableCompression = s.compression
 
 	return &s
 }
@@ -76,14 +90,33 @@ func NewServer(options ...Option) *Server {
 // it pushes the new entries to the connected clients.
 type Server struct {
 	addr, origin, certFile, keyFile string
-	reader                          watch.Reader
+	compression                     bool
+	compressionLevel                int
+	format                          OutputFormat
 	connections                     *Connections
 	upgrader                        websocket.Upgrader
+	authenticator                   Authenticator
+
+	readersMu sync.RWMutex
+	readers   map[string]watch.OffsetGetter
 }
 
 // HandleWS is an HTTP handler that upgrades incoming connections to WS or WSS.
 func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
-	// TODO: Add authentication support
+	var identity string
+
+	if s.authenticator != nil {
+		id, err := s.authenticator.Authenticate(r)
+		if err != nil {
+			log.Printf("authentication from %s failed: %v", r.RemoteAddr, err)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		identity = id
+		log.Printf("%s authenticated as %q", r.RemoteAddr, identity)
+	}
+
 	log.Printf("connection stablished with %s", r.RemoteAddr)
 
 	ws, err := s.upgrader.Upgrade(w, r, nil)
@@ -93,44 +126,23 @@ func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ws.SetReadLimit(maxMessageSize)
-
-	// Prepare keep alive protocol for the new connection
-	ws.SetReadDeadline(time.Now().Add(pongWait))
-	ws.SetPongHandler(func(string) error {
-		ws.SetReadDeadline(time.Now().Add(pongWait))
-		return nil
-	})
-
-	// Launch a gopher to keep connection alive
-	ctx, cancel := context.WithCancel(context.Background())
+	if s.compression {
+		ws.EnableWriteCompression(true)
+		ws.SetCompressionLevel(s.compressionLevel)
+	}
 
-	go func() {
-		ticker := time.NewTicker(pingPeriod)
-		defer ticker.Stop()
+	cl := newClient(ws, s.connections, s.format.messageType(), identity)
 
-		for {
-			select {
-			case <-ticker.C:
-				err := ws.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(writeWait))
-				if err != nil {
-					log.Printf("error sending ping: %v", err)
-					ws.Close()
-				}
-			case <-ctx.Done():
-				return
-			}
+	if source, offset, ok := s.resumeOffset(r, ws); ok {
+		if err := s.catchUp(cl, source, offset); err != nil {
+			log.Printf("catch-up for %s failed: %v", r.RemoteAddr, err)
 		}
-	}()
+	}
 
-	// Make sure to cleanup connection when closed
-	ws.SetCloseHandler(func(int, string) error {
-		log.Printf("closing connextion %s", ws.RemoteAddr())
-		cancel()
-		return s.connections.Delete(ws)
-	})
+	s.connections.Add(cl)
 
-	s.connections.Add(ws)
+	go cl.writePump()
+	go cl.readPump()
 }
 
 // Start starts a new HTTP server to listen for incoming WS or WSS connections.
@@ -169,16 +181,111 @@ func (s *Server) Start(ctx context.Context) error {
 	return g.Wait()
 }
 
-// Watch starts watching a transaction head file and broadcasts
-// the newly indexed transactions to all connected peers.
-func (s *Server) Watch(ctx context.Context, name string) error {
+// sourceLine is a line read from one of the tailed sources, fanned into
+// a single channel for the broadcaster to consume. offset is the byte
+// position right after this line in source, not the raw reader offset,
+// since bufio.Scanner can yield several buffered lines between reads.
+type sourceLine struct {
+	source string
+	data   []byte
+	offset int64
+}
+
+// Watch starts tailing every file matching pattern (a plain file name or
+// a glob, e.g. "*.log") and broadcasts the newly appended lines to all
+// connected peers. Files created later that match pattern are picked up
+// automatically.
+func (s *Server) Watch(ctx context.Context, pattern string) error {
+	s.readersMu.Lock()
+	s.readers = make(map[string]watch.OffsetGetter)
+	s.readersMu.Unlock()
+
+	lines := make(chan sourceLine)
+	g, ctx := errgroup.WithContext(ctx)
+
+	// Register the directory watch before taking the initial glob
+	// snapshot below. Doing it the other way round leaves a gap between
+	// the glob and fsnotify picking up events in which a newly created
+	// file is missed entirely: it's too late for the snapshot and, by
+	// the time the watch starts, it already exists so no Create event
+	// ever fires for it. This order can dispatch a name twice instead,
+	// which runSource and setReader already tolerate.
+	discovered, err := watch.WatchDir(ctx, pattern)
+	if err != nil {
+		return err
+	}
+
+	names, err := watch.Discover(pattern)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		name := name
+		g.Go(func() error { s.runSource(ctx, name, lines); return nil })
+	}
+
+	g.Go(func() error {
+		for name := range discovered {
+			name := name
+			g.Go(func() error { s.runSource(ctx, name, lines); return nil })
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		for {
+			select {
+			case sl := <-lines:
+				s.broadcast(sl.data, sl.offset, sl.source)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+
+	return g.Wait()
+}
+
+// runSource tails name until it is deleted, renamed or ctx is done,
+// logging the reason it stopped. A single source going away (ordinary
+// log-rotation behavior) must not tear down the other sources in the
+// same glob set, so its error is never returned to the shared errgroup.
+func (s *Server) runSource(ctx context.Context, name string, lines chan<- sourceLine) {
+	if err := s.tailSource(ctx, name, lines); err != nil && ctx.Err() == nil {
+		log.Printf("stopped tailing %s: %v", name, err)
+	}
+}
+
+// tailSource tails a single source file, reusing its watch.Reader across
+// overwrites, and sends every line it reads on lines.
+func (s *Server) tailSource(ctx context.Context, name string, lines chan<- sourceLine) error {
 	r := watch.NewReader(watch.SeekEnd())
+	s.setReader(name, r)
+	defer s.deleteReader(name)
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
+		var pos int64
 		scanner := bufio.NewScanner(r)
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			advance, token, err := bufio.ScanLines(data, atEOF)
+			if token != nil {
+				// data holds every byte read from r and not yet consumed,
+				// so it starts at GetOffset() - len(data).
+				pos = r.GetOffset() - int64(len(data)) + int64(advance)
+			}
+			return advance, token, err
+		})
 		for scanner.Scan() {
-			s.broadcast(scanner.Bytes())
+			line := append([]byte(nil), scanner.Bytes()...)
+
+			select {
+			case lines <- sourceLine{source: name, data: line, offset: pos}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 
 		return scanner.Err()
@@ -198,16 +305,55 @@ func (s *Server) Watch(ctx context.Context, name string) error {
 	return g.Wait()
 }
 
-func (s Server) broadcast(tx []byte) {
-	s.connections.Iter(func(ws *websocket.Conn) bool {
-		go func() {
-			ws.SetWriteDeadline(time.Now().Add(writeWait))
+func (s *Server) setReader(name string, r watch.OffsetGetter) {
+	s.readersMu.Lock()
+	s.readers[name] = r
+	s.readersMu.Unlock()
+}
 
-			if err := ws.WriteMessage(websocket.BinaryMessage, tx); err != nil {
-				log.Printf("tx broadcast failed: %v", err)
-				ws.Close()
-			}
-		}()
+func (s *Server) deleteReader(name string) {
+	s.readersMu.Lock()
+	delete(s.readers, name)
+	s.readersMu.Unlock()
+}
+
+// getReader returns the live reader for a named source.
+func (s *Server) getReader(name string) (watch.OffsetGetter, bool) {
+	s.readersMu.RLock()
+	defer s.readersMu.RUnlock()
+
+	r, ok := s.readers[name]
+	return r, ok
+}
+
+// soleSource returns the name of the live reader when exactly one source
+// is being tailed, for backwards compatible single-file offset resume.
+func (s *Server) soleSource() (string, bool) {
+	s.readersMu.RLock()
+	defer s.readersMu.RUnlock()
+
+	if len(s.readers) != 1 {
+		return "", false
+	}
+
+	for name := range s.readers {
+		return name, true
+	}
+
+	return "", false
+}
+
+func (s *Server) broadcast(line []byte, offset int64, source string) {
+	tx := s.encode(line, offset, source)
+
+	s.connections.Iter(func(cl *client) bool {
+		if !cl.Send(tx) {
+			// The client is too slow to keep up (or already gone), so
+			// drop it rather than blocking the broadcaster or buffering
+			// unbounded memory.
+			log.Printf("disconnecting slow client %s", cl.ws.RemoteAddr())
+			s.connections.Delete(cl)
+		}
 
 		return true
 	})
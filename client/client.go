@@ -0,0 +1,277 @@
+// Package client implements a reconnecting Websocket client for consuming
+// a tail-ws broadcast stream, suitable for use as a building block in
+// downstream pipelines.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	// ErrClosed is returned by Read once the client has been closed.
+	ErrClosed = errors.New("client closed")
+
+	// DefaultMinBackoff is the default initial delay before reconnecting.
+	DefaultMinBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the default upper bound for the reconnect delay.
+	DefaultMaxBackoff = 30 * time.Second
+
+	linesBufferSize = 256
+)
+
+// Option configures tail-ws clients.
+type Option func(*Client)
+
+// StartOffset sets the byte offset the server should resume from on the
+// first connection. Subsequent reconnects always resume from the last
+// offset observed by the client. Without this option the client never
+// sends an offset, so the server treats it as a plain live tail instead
+// of replaying history.
+func StartOffset(offset int64) Option {
+	return func(c *Client) {
+		c.offset = offset
+		c.hasOffset = true
+	}
+}
+
+// Backoff sets the minimum and maximum delay between reconnection attempts.
+// The delay starts at min and doubles on every failed attempt up to max.
+func Backoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = min
+		c.maxBackoff = max
+	}
+}
+
+// NewClient creates a new tail-ws client for the given server URL.
+// The URL scheme must be "ws" or "wss".
+func NewClient(addr string, options ...Option) *Client {
+	c := &Client{
+		addr:       addr,
+		dialer:     websocket.DefaultDialer,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		lines:      make(chan []byte, linesBufferSize),
+		closed:     make(chan struct{}),
+	}
+
+	for _, apply := range options {
+		apply(c)
+	}
+
+	c.pr, c.pw = io.Pipe()
+
+	return c
+}
+
+// Client dials a tail-ws server and reconnects with exponential backoff
+// when the connection drops, resuming from the last offset it observed.
+// It honors the HTTP_PROXY/HTTPS_PROXY environment variables, including
+// Proxy-Authorization for proxies with basic auth credentials in their
+// URL, since it dials through websocket.DefaultDialer.
+type Client struct {
+	addr       string
+	dialer     *websocket.Dialer
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu        sync.Mutex
+	offset    int64
+	hasOffset bool
+
+	lines chan []byte
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Lines returns a channel delivering each line received from the server.
+// The channel is closed when the client is closed.
+func (c *Client) Lines() <-chan []byte {
+	return c.lines
+}
+
+// Read implements io.Reader, delivering received lines newline-terminated.
+// It is an alternative to Lines and the two should not be used together,
+// since both are fed from the same underlying connection.
+func (c *Client) Read(p []byte) (int, error) {
+	return c.pr.Read(p)
+}
+
+// Offset returns the last byte offset observed by the client, suitable for
+// persisting and passing to StartOffset on a later run.
+func (c *Client) Offset() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.offset
+}
+
+// resumeOffset returns the offset dial should send to the server, and
+// whether one should be sent at all. It is false until StartOffset was
+// given or a server message has told the client its offset, so a plain
+// live-tail client never sends "offset=0" and accidentally asks the
+// server to replay from the beginning of the file.
+func (c *Client) resumeOffset() (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.offset, c.hasOffset
+}
+
+// Run dials the server and keeps the connection alive until ctx is
+// cancelled or the client is closed, reconnecting with exponential
+// backoff and resuming from the last observed offset on every attempt.
+func (c *Client) Run(ctx context.Context) error {
+	defer c.shutdown()
+
+	select {
+	case <-c.closed:
+		return nil
+	default:
+	}
+
+	backoff := c.minBackoff
+
+	for {
+		conn, err := c.dial(ctx)
+		if err != nil {
+			log.Printf("tail-ws dial failed: %v", err)
+		} else {
+			backoff = c.minBackoff
+
+			// Close the connection when ctx is done or the client is
+			// closed, so a blocked read does not keep Run going.
+			stop := make(chan struct{})
+			go func() {
+				select {
+				case <-ctx.Done():
+				case <-c.closed:
+				case <-stop:
+					return
+				}
+				conn.Close()
+			}()
+
+			err := c.readLoop(conn)
+			close(stop)
+			if err != nil {
+				log.Printf("tail-ws connection lost: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.closed:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// Close stops Run and releases the client's resources. It is safe to call
+// Close before Run, in which case Run returns immediately without dialing.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+
+	return nil
+}
+
+func (c *Client) dial(ctx context.Context) (*websocket.Conn, error) {
+	u, err := url.Parse(c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing server address: %w", err)
+	}
+
+	if offset, ok := c.resumeOffset(); ok {
+		q := u.Query()
+		q.Set("offset", fmt.Sprintf("%d", offset))
+		u.RawQuery = q.Encode()
+	}
+
+	conn, _, err := c.dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// envelope mirrors the "offset" field of the broadcast.FormatJSON wire
+// envelope. Offset is a pointer so a message that decodes as JSON but
+// carries no such field (or isn't an envelope at all) is told apart from
+// one that legitimately reports offset 0.
+type envelope struct {
+	Offset *int64 `json:"offset"`
+}
+
+func (c *Client) readLoop(conn *websocket.Conn) error {
+	defer conn.Close()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		c.trackOffset(msg)
+
+		select {
+		case c.lines <- msg:
+		default:
+			log.Printf("tail-ws client is falling behind, dropping a line")
+		}
+
+		if _, err := c.pw.Write(append(msg, '\n')); err != nil {
+			return err
+		}
+	}
+}
+
+// trackOffset updates the client's resume offset from msg. When the
+// server sends FormatJSON envelopes, their "offset" field is the
+// authoritative, per-line accurate position and is used as is. Otherwise
+// the offset is approximated by accumulating message lengths, plus the
+// newline the server's line scanner stripped from each one, on top of an
+// already known offset; without one the offset is left unknown.
+func (c *Client) trackOffset(msg []byte) {
+	var env envelope
+	if err := json.Unmarshal(msg, &env); err == nil && env.Offset != nil {
+		c.mu.Lock()
+		c.offset = *env.Offset
+		c.hasOffset = true
+		c.mu.Unlock()
+		return
+	}
+
+	c.mu.Lock()
+	if c.hasOffset {
+		c.offset += int64(len(msg)) + 1
+	}
+	c.mu.Unlock()
+}
+
+func (c *Client) shutdown() {
+	close(c.lines)
+	c.pw.CloseWithError(ErrClosed)
+}
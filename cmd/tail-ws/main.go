@@ -15,14 +15,17 @@ import (
 
 const usage = `Usage: tail-ws [OPTION]... FILE
 
-WebSocket broadcaster for appended file lines.
+WebSocket broadcaster for appended file lines. FILE may be a glob
+pattern (e.g. "*.log"), in which case every matching file is tailed and
+files created later that match the pattern are picked up automatically.
 
 Options:`
 
 func main() {
 	var (
-		addr, origin, certFile, keyFile string
-		verbose                         bool
+		addr, origin, certFile, keyFile, format, bearerTokenFile string
+		verbose, compress                                       bool
+		compressionLevel                                        int
 	)
 
 	flag.StringVar(&addr, "address", "127.0.0.1:8080", "server address")
@@ -30,10 +33,14 @@ func main() {
 	flag.BoolVar(&verbose, "verbose", false, "verbose output")
 	flag.StringVar(&certFile, "cert-file", "", "certificate file for WSS server")
 	flag.StringVar(&keyFile, "key-file", "", "private key file for WSS server")
+	flag.BoolVar(&compress, "compress", false, "enable permessage-deflate compression")
+	flag.IntVar(&compressionLevel, "compression-level", 1, "permessage-deflate compression level")
+	flag.StringVar(&format, "format", "binary", "output format: binary, text or json")
+	flag.StringVar(&bearerTokenFile, "bearer-token-file", "", "file with \"token identity\" pairs required to connect")
 	flag.Parse()
 
-	fileName := flag.Arg(0)
-	if fileName == "" {
+	pattern := flag.Arg(0)
+	if pattern == "" {
 		printUsage()
 		os.Exit(1)
 	}
@@ -42,15 +49,35 @@ func main() {
 		log.SetOutput(io.Discard)
 	}
 
-	server := broadcast.NewServer(
+	outputFormat, err := parseFormat(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	options := []broadcast.Option{
 		broadcast.Address(addr),
 		broadcast.Origin(origin),
 		broadcast.Secure(certFile, keyFile),
-	)
+		broadcast.Format(outputFormat),
+	}
+	if compress {
+		options = append(options, broadcast.EnableCompression(compressionLevel))
+	}
+	if bearerTokenFile != "" {
+		auth, err := broadcast.NewBearerAuthenticatorFromFile(bearerTokenFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		options = append(options, broadcast.WithAuth(auth))
+	}
+
+	server := broadcast.NewServer(options...)
 	g, ctx := errgroup.WithContext(context.Background())
 
 	g.Go(func() error {
-		return server.Watch(ctx, fileName)
+		return server.Watch(ctx, pattern)
 	})
 
 	g.Go(func() error {
@@ -67,3 +94,16 @@ func printUsage() {
 	fmt.Fprintln(os.Stderr, usage)
 	flag.PrintDefaults()
 }
+
+func parseFormat(format string) (broadcast.OutputFormat, error) {
+	switch format {
+	case "binary":
+		return broadcast.FormatBinary, nil
+	case "text":
+		return broadcast.FormatText, nil
+	case "json":
+		return broadcast.FormatJSON, nil
+	default:
+		return 0, fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -0,0 +1,63 @@
+package watch
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Discover returns the file names currently matching pattern, using the
+// same syntax as filepath.Glob.
+func Discover(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// WatchDir watches the directory of pattern for newly created files
+// matching pattern, sending each one's name on the returned channel. The
+// channel is closed once ctx is done.
+func WatchDir(ctx context.Context, pattern string) (<-chan string, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(pattern)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	found := make(chan string)
+
+	go func() {
+		defer watcher.Close()
+		defer close(found)
+
+		for {
+			select {
+			case e := <-watcher.Events:
+				if !e.Has(fsnotify.Create) {
+					continue
+				}
+
+				if ok, _ := filepath.Match(filepath.Base(pattern), filepath.Base(e.Name)); !ok {
+					continue
+				}
+
+				select {
+				case found <- e.Name:
+				case <-ctx.Done():
+					return
+				}
+			case <-watcher.Errors:
+				// Keep watching; a single misbehaving event should not
+				// stop discovery of the remaining sources.
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return found, nil
+}
@@ -174,9 +174,11 @@ func (r *reader) openFile(name string) (io.ReadSeekCloser, error) {
 			return nil, fmt.Errorf("error seeking file offset %d: %w", r.offset, err)
 		}
 	} else if r.seekEnd {
-		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		offset, err := f.Seek(0, io.SeekEnd)
+		if err != nil {
 			return nil, fmt.Errorf("error seeking file end: %w", err)
 		}
+		r.offset = offset
 	}
 
 	return f, nil
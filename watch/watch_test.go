@@ -0,0 +1,65 @@
+package watch
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestReaderSeekEndOffset verifies that GetOffset reports the real byte
+// position in the file after SeekEnd, not the number of bytes read since
+// watching started. A reader attached with SeekEnd to a file that
+// already has content used to report offsets starting from zero,
+// breaking resume for any existing-file tail (the normal case).
+func TestReaderSeekEndOffset(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.log")
+
+	seed := "existing content\n"
+	if err := os.WriteFile(name, []byte(seed), 0o644); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+
+	r := NewReader(SeekEnd())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- r.Watch(ctx, name) }()
+
+	// Give fsnotify time to register the watch before appending, so the
+	// write below is observed as an event rather than raced.
+	time.Sleep(100 * time.Millisecond)
+
+	appended := "new line\n"
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open for append: %v", err)
+	}
+	if _, err := f.WriteString(appended); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		t.Fatalf("expected a line, got none: %v", scanner.Err())
+	}
+	if got, want := scanner.Text(), "new line"; got != want {
+		t.Fatalf("line = %q, want %q", got, want)
+	}
+
+	wantOffset := int64(len(seed) + len(appended))
+	if got := r.GetOffset(); got != wantOffset {
+		t.Fatalf("GetOffset() = %d, want %d (true file position)", got, wantOffset)
+	}
+
+	cancel()
+	r.Close()
+	<-done
+}